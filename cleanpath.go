@@ -0,0 +1,81 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+// CleanPath returns the canonical form of p: duplicate slashes are collapsed, "."
+// segments are dropped, ".." segments are resolved against the preceding segment (or
+// dropped if there is none), and the result always begins with a single "/". A trailing
+// slash in p, other than the root itself, is preserved. Unlike path.Clean, CleanPath
+// operates purely on URL path syntax; it never consults the filesystem. It is adapted
+// from julienschmidt/httprouter's CleanPath and runs in a single pass over a byte
+// buffer.
+func CleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	n := len(p)
+	trailing := n > 1 && p[n-1] == '/'
+
+	buf := make([]byte, 1, n+1)
+	buf[0] = '/'
+
+	r := 1
+	for r < n {
+		switch {
+		case p[r] == '/':
+			// Duplicate slash; skip it.
+			r++
+
+		case p[r] == '.' && (r+1 == n || p[r+1] == '/'):
+			// "." segment; skip it and the slash that follows, if any.
+			r++
+			if r < n && p[r] == '/' {
+				r++
+			}
+
+		case p[r] == '.' && r+1 < n && p[r+1] == '.' && (r+2 == n || p[r+2] == '/'):
+			// ".." segment; skip it and back the write cursor up over the previous
+			// segment, if there is one to remove.
+			r += 2
+			if r < n && p[r] == '/' {
+				r++
+			}
+			if len(buf) > 1 {
+				buf = buf[:len(buf)-1]
+				for len(buf) > 1 && buf[len(buf)-1] != '/' {
+					buf = buf[:len(buf)-1]
+				}
+			}
+
+		default:
+			// A preceding ".." may have left buf ending in its slash already.
+			if buf[len(buf)-1] != '/' {
+				buf = append(buf, '/')
+			}
+			for r < n && p[r] != '/' {
+				buf = append(buf, p[r])
+				r++
+			}
+		}
+	}
+
+	// A ".." segment that lands exactly at the end of p pops back to the slash
+	// preceding the removed segment and leaves it in buf, even though p itself had no
+	// trailing slash there. Trim that artifact before applying the real trailing slash
+	// rule below.
+	if !trailing && len(buf) > 1 && buf[len(buf)-1] == '/' {
+		buf = buf[:len(buf)-1]
+	}
+
+	if trailing && buf[len(buf)-1] != '/' {
+		buf = append(buf, '/')
+	}
+
+	return string(buf)
+}
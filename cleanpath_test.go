@@ -0,0 +1,67 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanPath(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"person", "/person"},
+		{"/person//contacts", "/person/contacts"},
+		{"/person/./contacts", "/person/contacts"},
+		{"/person/../contacts", "/contacts"},
+		{"/person/contacts/..", "/person"},
+		{"/person/contacts/", "/person/contacts/"},
+		{"/../person", "/person"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.out, CleanPath(c.in), "CleanPath(%q)", c.in)
+	}
+}
+
+func TestMatchCleanPath(t *testing.T) {
+	var handler RouteMux
+	handler.CleanPath = true
+
+	err := handler.GET("/person/contacts", "details")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/person//contacts", &result)
+	assert.Equal(t, ErrRedirect, err)
+	assert.Equal(t, "/person/contacts", result.RedirectPath)
+}
+
+func TestMatchRedirectTrailingSlash(t *testing.T) {
+	var handler RouteMux
+	handler.RedirectTrailingSlash = true
+
+	err := handler.GET("/person/contacts", "details")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/person/contacts/", &result)
+	assert.Equal(t, ErrRedirect, err)
+	assert.Equal(t, "/person/contacts", result.RedirectPath)
+}
+
+func TestMatchNoRedirect(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/person/contacts", "details")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/person//contacts", &result)
+	assert.Equal(t, ErrNotFound, err)
+}
+
@@ -0,0 +1,142 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+// Route creates a sub-RouteMux scoped under prefix, passes it to fn for route
+// registration, and mounts the result at prefix. The sub-RouteMux inherits any
+// middleware already registered on m via Use, so middleware added inside fn is applied
+// in addition to it for routes registered inside fn. It also inherits m.StrictRegex, so
+// a ":name(expr)" variable registered inside fn anchors the same way it would if
+// registered directly on m. CleanPath and RedirectTrailingSlash are not inherited:
+// they are only consulted by the top-level Match/MatchRequest, never by routes
+// registered on a sub-RouteMux, which Mount only ever uses for its trie.
+func (m *RouteMux) Route(prefix string, fn func(r *RouteMux)) error {
+	sub := New()
+	sub.middleware = append([]Middleware{}, m.middleware...)
+	sub.StrictRegex = m.StrictRegex
+	fn(sub)
+	return m.Mount(prefix, sub)
+}
+
+// Mount splices sub's route trees into m at prefix, one HTTP method at a time, and does
+// the same for any Host-constrained trees sub registered via Host. Rather than
+// re-adding sub's routes one by one, sub's trie is walked node by node and merged into
+// m's trie at prefix, with each terminal route's data copied over and its paramNames
+// and segments rewritten to account for any path parameters captured while walking
+// prefix. Any routes sub registered with
+// AddNamedRoute remain reachable through m's URL/URLPath under the same name. Mount
+// merges sub's trie into whatever m already has at prefix rather than replacing it, and
+// returns ErrRouteExists if sub registers a route at the exact same pattern as one m
+// already has.
+func (m *RouteMux) Mount(prefix string, sub *RouteMux) error {
+	for method, subRoot := range sub.methods {
+		node, prefixParams, err := m.resolveNode(method, prefix)
+		if err != nil {
+			return err
+		}
+
+		if err := m.spliceNamed(sub, node, subRoot, prefixParams, node.segments); err != nil {
+			return err
+		}
+	}
+
+	for method, subHostRoutes := range sub.hosts {
+		for _, hr := range subHostRoutes {
+			root := m.hostRoot(method, hr.host.raw)
+			node, prefixParams, err := resolveNodeFrom(root, prefix, m.StrictRegex)
+			if err != nil {
+				return err
+			}
+
+			if err := m.spliceNamed(sub, node, hr.route, prefixParams, node.segments); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// spliceNamed merges subRoot into dst via spliceRoute and, for any of sub's named
+// routes that landed among the copied nodes, relinks them into m.names so they remain
+// reachable through m's URL/URLPath. It is shared by Mount's method-root and
+// Host-constrained splicing passes.
+func (m *RouteMux) spliceNamed(sub *RouteMux, dst *route, subRoot *route, prefixParams []string, prefixSegments []urlSegment) error {
+	mapping := make(map[*route]*route, 16)
+	if err := spliceRoute(dst, subRoot, prefixParams, prefixSegments, mapping); err != nil {
+		return err
+	}
+
+	for name, r := range sub.names {
+		newRoute, ok := mapping[r]
+		if !ok {
+			continue
+		}
+
+		if m.names == nil {
+			m.names = make(map[string]*route, 10)
+		}
+		m.names[name] = newRoute
+	}
+
+	return nil
+}
+
+// spliceRoute merges sub into dst, recording every copied node in mapping so that sub's
+// named routes can be relinked to their new location after mounting, and prepends
+// prefixParams/prefixSegments to the paramNames/segments of every descendant terminal
+// route so Match results and URL reconstruction account for the mount prefix. Unlike a
+// plain assignment, dst's existing children and data are preserved: sub's children are
+// merged in alongside them, and sub's data only clobbers dst's if the pattern was not
+// already registered, for which it instead returns ErrRouteExists.
+func spliceRoute(dst *route, sub *route, prefixParams []string, prefixSegments []urlSegment, mapping map[*route]*route) error {
+	mapping[sub] = dst
+
+	if sub.data != nil {
+		if dst.data != nil {
+			return ErrRouteExists
+		}
+
+		dst.data = sub.data
+		dst.paramNames = append(append([]string{}, prefixParams...), sub.paramNames...)
+		dst.segments = append(append([]urlSegment{}, prefixSegments...), sub.segments...)
+	}
+
+	dst.wildcard = dst.wildcard || sub.wildcard
+	dst.headers = append(dst.headers, sub.headers...)
+	dst.transcodes = append(dst.transcodes, sub.transcodes...)
+
+	if sub.variable != nil {
+		if dst.variable == nil {
+			dst.variable = &route{}
+		}
+		if err := spliceRoute(dst.variable, sub.variable, prefixParams, prefixSegments, mapping); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range sub.variables {
+		target := dst.variableChild(v.expr, v.regex)
+		if err := spliceRoute(target, v.route, prefixParams, prefixSegments, mapping); err != nil {
+			return err
+		}
+	}
+
+	for i, part := range sub.indices {
+		target := dst.staticChild(part)
+		if err := spliceRoute(target, sub.static[i], prefixParams, prefixSegments, mapping); err != nil {
+			return err
+		}
+	}
+
+	for part, s := range sub.staticMap {
+		target := dst.staticChild(part)
+		if err := spliceRoute(target, s, prefixParams, prefixSegments, mapping); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMount(t *testing.T) {
+	var handler RouteMux
+
+	sub := New()
+	err := sub.GET("/users/:id", "user")
+	require.Nil(t, err)
+
+	err = handler.Mount("/api/v1", sub)
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/api/v1/users/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "user", result.Data)
+	assert.Equal(t, "42", result.Param("id"))
+}
+
+func TestMountPreservesExistingRoutes(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/api/v1/ping", "ping")
+	require.Nil(t, err)
+
+	sub := New()
+	err = sub.GET("/users/:id", "user")
+	require.Nil(t, err)
+
+	err = handler.Mount("/api/v1", sub)
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/api/v1/ping", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "ping", result.Data)
+
+	err = handler.Match(GET, "/api/v1/users/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "user", result.Data)
+	assert.Equal(t, "42", result.Param("id"))
+}
+
+func TestMountRouteCollision(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/api/v1/ping", "ping1")
+	require.Nil(t, err)
+
+	sub := New()
+	err = sub.GET("/ping", "ping2")
+	require.Nil(t, err)
+
+	err = handler.Mount("/api/v1", sub)
+	assert.Equal(t, ErrRouteExists, err)
+}
+
+func TestRouteInheritsMiddleware(t *testing.T) {
+	var handler RouteMux
+	var order []string
+
+	handler.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "outer")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	err := handler.Route("/api/v1", func(r *RouteMux) {
+		err := r.HandleFunc(GET, "/ping", func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "handler")
+		})
+		require.Nil(t, err)
+	})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/api/v1/ping", nil)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, []string{"outer", "handler"}, order)
+}
@@ -0,0 +1,228 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"net/http"
+	"strings"
+)
+
+// headerMatch is a single required header key/value pair, as registered via
+// RouteMux.Headers or RouteBuilder.Headers.
+type headerMatch struct {
+	key   string
+	value string
+}
+
+// hostPattern is a compiled Host matcher. Each label may be a literal (e.g. "example")
+// or a ":name" variable capturing that label.
+type hostPattern struct {
+	raw    string
+	labels []string
+}
+
+func compileHost(pattern string) *hostPattern {
+	return &hostPattern{raw: pattern, labels: strings.Split(pattern, ".")}
+}
+
+// match reports whether host (which may include a ":port" suffix, stripped before
+// comparison) satisfies p, returning the params bound by any ":name" labels.
+func (p *hostPattern) match(host string) ([]Param, bool) {
+	if idx := strings.IndexByte(host, ':'); idx != -1 {
+		host = host[:idx]
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) != len(p.labels) {
+		return nil, false
+	}
+
+	var params []Param
+	for i, label := range p.labels {
+		if strings.HasPrefix(label, ":") {
+			params = append(params, Param{Name: label[1:], Value: labels[i]})
+		} else if label != labels[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// hostRoute pairs a compiled Host matcher with the path trie reachable when it matches.
+type hostRoute struct {
+	host  *hostPattern
+	route *route
+}
+
+// Host returns a RouteBuilder that additionally requires the request's Host header to
+// match pattern, which may contain ":name" labels (e.g. ":tenant.example.com").
+func (m *RouteMux) Host(pattern string) *RouteBuilder {
+	return (&RouteBuilder{mux: m}).Host(pattern)
+}
+
+// Headers returns a RouteBuilder that additionally requires the request to carry a
+// header named key with the exact value given.
+func (m *RouteMux) Headers(key, value string) *RouteBuilder {
+	return (&RouteBuilder{mux: m}).Headers(key, value)
+}
+
+// Host constrains routes registered through b to requests whose Host header matches
+// pattern.
+func (b *RouteBuilder) Host(pattern string) *RouteBuilder {
+	b.host = pattern
+	return b
+}
+
+// Headers adds a required header key/value pair to routes registered through b.
+func (b *RouteBuilder) Headers(key, value string) *RouteBuilder {
+	b.headers = append(b.headers, headerMatch{key: key, value: value})
+	return b
+}
+
+// hostRoot returns the path trie root for method under host, creating it (and
+// compiling host) the first time it is requested.
+func (m *RouteMux) hostRoot(method, host string) *route {
+	if m.hosts == nil {
+		m.hosts = make(map[string][]*hostRoute, 10)
+	}
+
+	for _, hr := range m.hosts[method] {
+		if hr.host.raw == host {
+			return hr.route
+		}
+	}
+
+	hr := &hostRoute{host: compileHost(host), route: &route{}}
+	m.hosts[method] = append(m.hosts[method], hr)
+	return hr.route
+}
+
+// AddRoute registers data for method and pattern, honoring any Host/Headers
+// constraints carried by b, the same way RouteMux.AddRoute does for an unconstrained
+// route.
+func (b *RouteBuilder) AddRoute(method, pattern string, data interface{}) error {
+	return b.addRouteConstrained(method, pattern, data)
+}
+
+// GET adds a new route for GET requests.
+func (b *RouteBuilder) GET(pattern string, details interface{}) error {
+	return b.AddRoute(GET, pattern, details)
+}
+
+// PUT adds a new route for PUT requests.
+func (b *RouteBuilder) PUT(pattern string, details interface{}) error {
+	return b.AddRoute(PUT, pattern, details)
+}
+
+// DELETE adds a new route for DELETE requests.
+func (b *RouteBuilder) DELETE(pattern string, details interface{}) error {
+	return b.AddRoute(DELETE, pattern, details)
+}
+
+// PATCH adds a new route for PATCH requests.
+func (b *RouteBuilder) PATCH(pattern string, details interface{}) error {
+	return b.AddRoute(PATCH, pattern, details)
+}
+
+// POST adds a new route for POST requests.
+func (b *RouteBuilder) POST(pattern string, details interface{}) error {
+	return b.AddRoute(POST, pattern, details)
+}
+
+// addRouteConstrained registers data for method and pattern, honoring any Host/Headers
+// constraints carried by b.
+func (b *RouteBuilder) addRouteConstrained(method, pattern string, data interface{}) error {
+	var r *route
+	var paramNames []string
+	var err error
+
+	if b.host == "" {
+		r, paramNames, err = b.mux.resolveNode(method, pattern)
+	} else {
+		root := b.mux.hostRoot(method, b.host)
+		r, paramNames, err = resolveNodeFrom(root, pattern, b.mux.StrictRegex)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.data = data
+	r.paramNames = paramNames
+	r.headers = b.headers
+
+	return nil
+}
+
+// headersMatch reports whether header satisfies every constraint in want.
+func headersMatch(want []headerMatch, header http.Header) bool {
+	for _, h := range want {
+		if header.Get(h.key) != h.value {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchRequest matches r against routes registered with Host/Headers constraints, in
+// addition to the plain method+path trie Match consults. Host-constrained routes are
+// tried first, in registration order; Headers constraints are enforced for both
+// host-constrained and unconstrained routes. As with Match, if no route matches the
+// request path itself but m.CleanPath or m.RedirectTrailingSlash is enabled and a
+// canonicalized form of it does, MatchRequest returns ErrRedirect with the canonical
+// path set in result.RedirectPath.
+func (m *RouteMux) MatchRequest(r *http.Request, result *Result) error {
+	return m.retryClean(r.URL.Path, result, func(path string) (bool, error) {
+		return m.matchRequestPath(r, path, result)
+	})
+}
+
+// matchRequestPath is MatchRequest's single-attempt implementation, run directly against
+// path rather than always r.URL.Path so that retryClean can retry it against a
+// canonicalized path. It reports whether reaching the match required stripping a
+// trailing slash from path.
+func (m *RouteMux) matchRequestPath(r *http.Request, path string, result *Result) (bool, error) {
+	result.Data = nil
+	result.Params = result.params[:0]
+	result.node = nil
+
+	for _, hr := range m.hosts[r.Method] {
+		hostParams, ok := hr.host.match(r.Host)
+		if !ok {
+			continue
+		}
+
+		var pathResult Result
+		node, trimmedSlash, err := matchPath(hr.route, path, &pathResult)
+		if err != nil || !headersMatch(node.headers, r.Header) {
+			continue
+		}
+
+		result.Data = pathResult.Data
+		result.Params = append(result.Params[:0], hostParams...)
+		result.Params = append(result.Params, pathResult.Params...)
+		result.node = node
+		return trimmedSlash, nil
+	}
+
+	root, ok := m.methods[r.Method]
+	if !ok {
+		return false, ErrNotFound
+	}
+
+	node, trimmedSlash, err := matchPath(root, path, result)
+	if err != nil {
+		return false, err
+	}
+	result.node = node
+
+	if !headersMatch(node.headers, r.Header) {
+		result.Data = nil
+		result.node = nil
+		return false, ErrNotFound
+	}
+
+	return trimmedSlash, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostMatch(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.Host(":tenant.example.com").GET("/widgets", "tenant-widgets")
+	require.Nil(t, err)
+	err = handler.GET("/widgets", "default-widgets")
+	require.Nil(t, err)
+
+	req := httptest.NewRequest(GET, "http://acme.example.com/widgets", nil)
+	err = handler.MatchRequest(req, &result)
+	require.Nil(t, err)
+	assert.Equal(t, "tenant-widgets", result.Data)
+	assert.Equal(t, "acme", result.Param("tenant"))
+
+	req = httptest.NewRequest(GET, "http://unrelated.org/widgets", nil)
+	err = handler.MatchRequest(req, &result)
+	require.Nil(t, err)
+	assert.Equal(t, "default-widgets", result.Data)
+}
+
+func TestHeadersMatch(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.Headers("X-API-Version", "2").GET("/widgets", "v2-widgets")
+	require.Nil(t, err)
+
+	req := httptest.NewRequest(GET, "/widgets", nil)
+	req.Header.Set("X-API-Version", "2")
+	err = handler.MatchRequest(req, &result)
+	require.Nil(t, err)
+	assert.Equal(t, "v2-widgets", result.Data)
+
+	req = httptest.NewRequest(GET, "/widgets", nil)
+	err = handler.MatchRequest(req, &result)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestMatchRequestCleanPathRedirect(t *testing.T) {
+	var handler RouteMux
+	handler.CleanPath = true
+
+	err := handler.HandleFunc(GET, "/widgets", func(w http.ResponseWriter, r *http.Request) {})
+	require.Nil(t, err)
+
+	req := httptest.NewRequest(GET, "/widgets/../widgets", nil)
+	err = handler.MatchRequest(req, &result)
+	assert.Equal(t, ErrRedirect, err)
+	assert.Equal(t, "/widgets", result.RedirectPath)
+}
+
+func TestServeHTTPCleanPathRedirect(t *testing.T) {
+	var handler RouteMux
+	handler.CleanPath = true
+
+	err := handler.HandleFunc(GET, "/foo/bar", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be invoked for the uncleaned path")
+	})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/foo//bar", nil)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/foo/bar", w.Header().Get("Location"))
+}
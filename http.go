@@ -0,0 +1,138 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps an http.Handler with additional behavior. Middleware is composed in
+// the order it is registered: the first middleware passed to Use or With runs first.
+type Middleware func(http.Handler) http.Handler
+
+type contextKey int
+
+const resultContextKey contextKey = iota
+
+// Use appends middleware that is applied to every route registered through
+// Handle/HandleFunc after the call to Use. It has no effect on routes already registered.
+func (m *RouteMux) Use(middleware ...Middleware) {
+	m.middleware = append(m.middleware, middleware...)
+}
+
+// With returns a RouteBuilder that additionally applies middleware to any route
+// registered through it, on top of the middleware already registered via Use.
+func (m *RouteMux) With(middleware ...Middleware) *RouteBuilder {
+	return &RouteBuilder{mux: m, middleware: middleware}
+}
+
+// RouteBuilder registers routes with an additional per-route middleware chain and/or
+// Host/Headers constraint, as returned by RouteMux.With, RouteMux.Host, or
+// RouteMux.Headers.
+type RouteBuilder struct {
+	mux        *RouteMux
+	middleware []Middleware
+	host       string
+	headers    []headerMatch
+}
+
+// Handle registers h as the http.Handler for method and pattern.
+func (b *RouteBuilder) Handle(method, pattern string, h http.Handler) error {
+	wrapped := applyMiddleware(h, b.mux.middleware, b.middleware)
+	return b.addRouteConstrained(method, pattern, wrapped)
+}
+
+// HandleFunc registers h as the http.HandlerFunc for method and pattern.
+func (b *RouteBuilder) HandleFunc(method, pattern string, h http.HandlerFunc) error {
+	return b.Handle(method, pattern, h)
+}
+
+// With returns a RouteBuilder that additionally applies middleware, composed with any
+// middleware and Host/Headers constraints already set on b.
+func (b *RouteBuilder) With(middleware ...Middleware) *RouteBuilder {
+	return &RouteBuilder{
+		mux:        b.mux,
+		middleware: append(append([]Middleware{}, b.middleware...), middleware...),
+		host:       b.host,
+		headers:    b.headers,
+	}
+}
+
+// Handle registers h as the http.Handler for method and pattern, wrapped with any
+// middleware registered via Use.
+func (m *RouteMux) Handle(method, pattern string, h http.Handler) error {
+	return m.handle(method, pattern, h, nil)
+}
+
+// HandleFunc registers h as the http.HandlerFunc for method and pattern, wrapped with any
+// middleware registered via Use.
+func (m *RouteMux) HandleFunc(method, pattern string, h http.HandlerFunc) error {
+	return m.Handle(method, pattern, h)
+}
+
+// handle wraps h with m's middleware followed by extra, then stores the wrapped handler
+// as the route's data, the same way AddRoute stores arbitrary data.
+func (m *RouteMux) handle(method, pattern string, h http.Handler, extra []Middleware) error {
+	wrapped := applyMiddleware(h, m.middleware, extra)
+	return m.AddRoute(method, pattern, wrapped)
+}
+
+// applyMiddleware wraps h with chains applied outermost first.
+func applyMiddleware(h http.Handler, chains ...[]Middleware) http.Handler {
+	for i := len(chains) - 1; i >= 0; i-- {
+		chain := chains[i]
+		for j := len(chain) - 1; j >= 0; j-- {
+			h = chain[j](h)
+		}
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler, allowing a RouteMux to be used as a drop-in HTTP
+// router. On a successful match, the Result is injected into the request context
+// (retrievable with ParamsFromContext or RequestParam) and the stored http.Handler is invoked.
+// If the request path itself doesn't match but m.CleanPath or m.RedirectTrailingSlash
+// is enabled and its canonicalized form does, ServeHTTP issues a redirect to it. If no
+// route matches at all, http.NotFound is called.
+func (m *RouteMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var result Result
+	err := m.MatchRequest(r, &result)
+	if err == ErrRedirect {
+		http.Redirect(w, r, result.RedirectPath, http.StatusMovedPermanently)
+		return
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h, ok := result.Data.(http.Handler)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), resultContextKey, &result)
+	h.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ParamsFromContext returns the Result that ServeHTTP stored in ctx, or nil if ctx was
+// not derived from a request handled by a RouteMux.
+func ParamsFromContext(ctx context.Context) *Result {
+	result, _ := ctx.Value(resultContextKey).(*Result)
+	return result
+}
+
+// RequestParam returns the path parameter value for name from the Result stored in r's
+// context by ServeHTTP. It returns "" if r was not handled by a RouteMux or name is not a
+// parameter of the matched route.
+func RequestParam(r *http.Request, name string) string {
+	result := ParamsFromContext(r.Context())
+	if result == nil {
+		return ""
+	}
+	return result.Param(name)
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTP(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.HandleFunc(GET, "/person/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(RequestParam(r, "id")))
+	})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/person/42", nil)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42", w.Body.String())
+}
+
+func TestServeHTTPNotFound(t *testing.T) {
+	var handler RouteMux
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/nope", nil)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var handler RouteMux
+	var order []string
+
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler.Use(trace("outer"))
+	err := handler.With(trace("inner")).HandleFunc(GET, "/ping", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/ping", nil)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestParamsFromContext(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.HandleFunc(GET, "/person/:id", func(w http.ResponseWriter, r *http.Request) {
+		result := ParamsFromContext(r.Context())
+		require.NotNil(t, result)
+		assert.Equal(t, "42", result.Param("id"))
+	})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(GET, "/person/42", nil)
+	handler.ServeHTTP(w, r)
+}
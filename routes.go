@@ -30,6 +30,13 @@ var (
 	ErrBadSyntax = errors.New("Path contained invalid syntax")
 	// ErrWildcardMisplaced denotes that a wildcard was encountered before the end of the pattern.
 	ErrWildcardMisplaced = errors.New("Wildcard must be at the end of the path")
+	// ErrRedirect denotes that the request path itself did not match, but its
+	// canonicalized form (see RouteMux.CleanPath and RouteMux.RedirectTrailingSlash)
+	// does; the canonical path is set in Result.RedirectPath.
+	ErrRedirect = errors.New("Canonical form of the path matches a route")
+	// ErrRouteExists denotes that Mount could not splice a sub-router's route in
+	// because a route was already registered at that exact pattern.
+	ErrRouteExists = errors.New("A route is already registered at this pattern")
 )
 
 type (
@@ -47,8 +54,21 @@ type (
 		data       interface{}
 		paramNames []string
 
+		// segments records this route's pattern, one entry per path part, so that a
+		// registered name can later be reversed back into a concrete path by URL.
+		segments []urlSegment
+
 		// Wildcard flag
 		wildcard bool
+
+		// transcodes holds routes registered via AddTranscodeRoute, tried against
+		// whatever of the request path remains at this node once none of the
+		// static/variable fields above match, and before falling back to a wildcard.
+		transcodes []*transcodeRoute
+
+		// headers, if non-empty, lists header constraints (see RouteMux.Headers) that
+		// MatchRequest additionally requires of this route.
+		headers []headerMatch
 	}
 
 	variableRoute struct {
@@ -60,6 +80,31 @@ type (
 	// RouteMux stores root level routes per HTTP method.
 	RouteMux struct {
 		methods map[string]*route
+
+		// middleware is applied, outermost first, to every route registered through
+		// Handle/HandleFunc after it was added via Use.
+		middleware []Middleware
+
+		// names indexes routes registered via AddNamedRoute for reverse lookup by URL.
+		names map[string]*route
+
+		// CleanPath, if true, makes Match retry a failed lookup once against the
+		// canonicalized form of the request path (see CleanPath).
+		CleanPath bool
+		// RedirectTrailingSlash, if true, makes Match redirect to the trailing-slash-free
+		// form of the request path whenever that form is the only reason a lookup
+		// matched at all (matchPath otherwise strips a trailing slash and matches
+		// silently).
+		RedirectTrailingSlash bool
+
+		// hosts holds, per method, the path tries registered via Host, tried in
+		// registration order by MatchRequest before the method's default trie.
+		hosts map[string][]*hostRoute
+
+		// StrictRegex, if true, preserves this package's historical behavior of
+		// matching a ":name(expr)" variable's expression unanchored, so expr need only
+		// match a substring of the path part rather than the whole of it.
+		StrictRegex bool
 	}
 
 	// Param encapsulates a name/value pair.
@@ -73,6 +118,14 @@ type (
 		Data   interface{}
 		Params []Param
 		params [10]Param // internal array that initially backs Params to prevent allocations
+
+		// RedirectPath is set by Match, alongside ErrRedirect, to the canonical path
+		// that matched when the original request path did not.
+		RedirectPath string
+
+		// node is the matched trie node, set by Match and MatchRequest alongside Data
+		// and reset to nil when nothing matches.
+		node *route
 	}
 )
 
@@ -110,32 +163,63 @@ func (m *RouteMux) POST(pattern string, details interface{}) error {
 
 // AddRoute adds a new route to that stores to the provided data.
 func (m *RouteMux) AddRoute(method string, pattern string, data interface{}) error {
-	// Remove leading and trailing slashes and split the url into sections.
-	l := len(pattern)
-	for l > 0 && pattern[0] == '/' {
-		pattern = pattern[1:]
-		l--
-	}
-	for l > 0 && pattern[l-1] == '/' {
-		pattern = pattern[:l-1]
-		l--
+	r, paramNames, err := m.resolveNode(method, pattern)
+	if err != nil {
+		return err
 	}
 
-	// Initialize methods map, if needed.
+	// Set the data and parameter names.
+	r.data = data
+	r.paramNames = paramNames
+
+	return nil
+}
+
+// methodRoot returns the root route for method, creating the methods map and the root
+// route itself if this is the first route registered for method.
+func (m *RouteMux) methodRoot(method string) *route {
 	if m.methods == nil {
 		m.methods = make(map[string]*route, 10)
 	}
 
-	// Get root route from method map.
 	r, ok := m.methods[method]
 	if !ok {
 		r = &route{}
 		m.methods[method] = r
 	}
 
+	return r
+}
+
+// resolveNode walks (creating nodes as needed) the trie for method down the given
+// pattern, returning the node at the end of the pattern along with the path parameter
+// names encountered along the way. It does not set the node's data or paramNames, so it
+// can be shared by AddRoute, Mount, and other trie-building APIs that need to reach a
+// node before deciding what to store there.
+func (m *RouteMux) resolveNode(method string, pattern string) (*route, []string, error) {
+	return resolveNodeFrom(m.methodRoot(method), pattern, m.StrictRegex)
+}
+
+// resolveNodeFrom is resolveNode's underlying implementation, generalized to walk down
+// from an arbitrary root rather than always a method's default root trie. This lets
+// host-constrained routes (see RouteMux.Host) build their own trie per host while
+// reusing the same pattern-parsing logic. Unless strict is true, a ":name(expr)"
+// variable's expression is anchored with "^(?:expr)$" so it must match a whole path
+// part rather than just a substring of it.
+func resolveNodeFrom(r *route, pattern string, strict bool) (*route, []string, error) {
+	// Remove leading and trailing slashes and split the url into sections.
+	l := len(pattern)
+	for l > 0 && pattern[0] == '/' {
+		pattern = pattern[1:]
+		l--
+	}
+	for l > 0 && pattern[l-1] == '/' {
+		pattern = pattern[:l-1]
+		l--
+	}
+
 	if l == 0 {
-		r.data = data
-		return nil
+		return r, nil, nil
 	}
 
 	parts := strings.Split(pattern, "/")
@@ -143,17 +227,19 @@ func (m *RouteMux) AddRoute(method string, pattern string, data interface{}) err
 	// Check for misplaced wildcard parts.
 	for i, part := range parts {
 		if part == "*" && i != len(parts)-1 {
-			return ErrWildcardMisplaced
+			return nil, nil, ErrWildcardMisplaced
 		}
 	}
 
-	// Create a slice to capture path parameter names.
+	// Create slices to capture path parameter names and, for reverse routing, the
+	// pattern's segments.
 	var paramNames = make([]string, 0, 10)
+	var segments = make([]urlSegment, 0, len(parts))
 
 walk:
 	for _, part := range parts {
 		if len(part) == 0 {
-			return ErrBadSyntax
+			return nil, nil, ErrBadSyntax
 		}
 
 		// Find params that start with ":" and create variable routes.
@@ -170,6 +256,8 @@ walk:
 			paramNames = append(paramNames, part[1:])
 
 			if expr == "" {
+				segments = append(segments, urlSegment{param: part[1:]})
+
 				// No custom regexp defined.
 				if r.variable != nil {
 					r = r.variable
@@ -184,17 +272,26 @@ walk:
 				// Find existing regexp.
 				for _, v := range r.variables {
 					if v.expr == expr {
+						segments = append(segments, urlSegment{param: part[1:], regex: v.regex})
 						r = v.route
 						continue walk
 					}
 				}
 
-				// Compile the new expression.
-				regex, regexErr := regexp.Compile(expr)
+				// Compile the new expression, anchoring it to the whole path part
+				// unless StrictRegex asks us to preserve the old substring-matching
+				// behavior.
+				toCompile := expr
+				if !strict {
+					toCompile = "^(?:" + expr + ")$"
+				}
+				regex, regexErr := regexp.Compile(toCompile)
 				if regexErr != nil {
-					return regexErr
+					return nil, nil, regexErr
 				}
 
+				segments = append(segments, urlSegment{param: part[1:], regex: regex})
+
 				// Create the new variable route.
 				next := &variableRoute{
 					expr:  expr,
@@ -212,87 +309,183 @@ walk:
 			}
 		} else if part == "*" {
 			// Wildcard part
+			segments = append(segments, urlSegment{param: "*", wildcard: true})
 			r.wildcard = true
 		} else {
+			segments = append(segments, urlSegment{literal: part})
+
 			// Static part
-			var next *route
+			r = r.staticChild(part)
+		}
+	}
 
-			// If non-nil, use the static map to find an existing route.
-			if r.staticMap != nil {
-				next = r.staticMap[part]
-				if next == nil {
-					next = &route{}
-				}
+	r.segments = segments
 
-				r.staticMap[part] = next
-			} else {
-				// Initialize the parallel slices, if needed.
-				if r.indices == nil {
-					r.indices = make([]string, 0, 10)
-					r.static = make([]*route, 0, 10)
-				}
+	return r, paramNames, nil
+}
 
-				// Find existing static route.
-				for i, v := range r.indices {
-					if v == part {
-						next = r.static[i]
-						break
-					}
-				}
+// staticChild returns r's static child route for part, creating it (and converting r's
+// parallel indices/static slices to staticMap, once there are enough children to warrant
+// it) if this is the first time part has been reached.
+func (r *route) staticChild(part string) *route {
+	// If non-nil, use the static map to find an existing route.
+	if r.staticMap != nil {
+		next := r.staticMap[part]
+		if next == nil {
+			next = &route{}
+			r.staticMap[part] = next
+		}
 
-				// A new route must be created.
-				if next == nil {
-					next = &route{}
-
-					// Is the count of static routes enough to warrant a map instead of a slice.
-					if len(r.indices) >= 5 {
-						// Convert the parallel slices to a map.
-						r.staticMap = make(map[string]*route, 25)
-						for i, v := range r.indices {
-							r.staticMap[v] = r.static[i]
-						}
-
-						r.staticMap[part] = next
-
-						// Allow slices to be GC'd
-						r.indices = nil
-						r.static = nil
-					} else {
-						r.indices = append(r.indices, part)
-						r.static = append(r.static, next)
-					}
-				}
-			}
+		return next
+	}
 
-			r = next
+	// Find existing static route.
+	for i, v := range r.indices {
+		if v == part {
+			return r.static[i]
 		}
 	}
 
-	// Set the data and parameter names.
-	r.data = data
-	r.paramNames = paramNames
+	// A new route must be created.
+	next := &route{}
 
-	return nil
+	// Is the count of static routes enough to warrant a map instead of a slice.
+	if len(r.indices) >= 5 {
+		// Convert the parallel slices to a map.
+		r.staticMap = make(map[string]*route, 25)
+		for i, v := range r.indices {
+			r.staticMap[v] = r.static[i]
+		}
+
+		r.staticMap[part] = next
+
+		// Allow slices to be GC'd
+		r.indices = nil
+		r.static = nil
+	} else {
+		if r.indices == nil {
+			r.indices = make([]string, 0, 10)
+			r.static = make([]*route, 0, 10)
+		}
+
+		r.indices = append(r.indices, part)
+		r.static = append(r.static, next)
+	}
+
+	return next
+}
+
+// variableChild returns r's regex-variable child route for expr, creating it (with
+// regex) if this is the first time expr has been reached, mirroring resolveNodeFrom's
+// own expr-matching behavior.
+func (r *route) variableChild(expr string, regex *regexp.Regexp) *route {
+	for _, v := range r.variables {
+		if v.expr == expr {
+			return v.route
+		}
+	}
+
+	next := &variableRoute{
+		expr:  expr,
+		route: &route{},
+		regex: regex,
+	}
+	r.variables = append(r.variables, next)
+
+	return next.route
 }
 
-// Match tries to find a match for the provided method and request path.   If a match is found,
-// details and path paramater values are set in result.
+// Match tries to find a match for the provided method and request path. If a match is
+// found, details and path paramater values are set in result. If no match is found but
+// m.CleanPath or m.RedirectTrailingSlash is enabled and a canonicalized form of
+// requestPath does match, Match returns ErrRedirect with the canonical path set in
+// result.RedirectPath, so that callers using this as an HTTP handler can issue a
+// redirect instead of a 404.
 func (m *RouteMux) Match(method string, requestPath string, result *Result) error {
+	return m.retryClean(requestPath, result, func(path string) (bool, error) {
+		return m.match(method, path, result)
+	})
+}
+
+// retryClean runs attempt against requestPath. attempt reports, alongside its error,
+// whether reaching that result required stripping a trailing slash from path; retryClean
+// uses that to tell an exact match from one that only succeeded because matchPath
+// normalizes trailing slashes. If the first attempt only matched that way and
+// m.RedirectTrailingSlash is enabled, retryClean redirects to the trailing-slash-free
+// form instead of matching silently. If the first attempt returned ErrNotFound and
+// m.CleanPath is enabled, it retries once against requestPath's canonicalized form;
+// m.RedirectTrailingSlash contributes nothing to this retry, since matchPath already
+// strips any trailing slash before matching, so toggling one on the candidate can never
+// change whether it matches. On a successful retry, retryClean returns ErrRedirect with
+// the canonical path set in result.RedirectPath, so that callers using this as an HTTP
+// handler can issue a redirect instead of a 404. It is shared by Match and MatchRequest
+// so both retry the same way.
+func (m *RouteMux) retryClean(requestPath string, result *Result, attempt func(path string) (bool, error)) error {
+	trimmedSlash, err := attempt(requestPath)
+	if err == nil {
+		if !m.RedirectTrailingSlash || !trimmedSlash {
+			return nil
+		}
+		// The only reason this succeeded is that matchPath stripped requestPath's
+		// trailing slash; redirect to that canonical form instead of matching silently.
+	} else if err != ErrNotFound || !m.CleanPath {
+		return err
+	}
+
+	var candidate string
+	if err == nil {
+		candidate = strings.TrimRight(requestPath, "/")
+	} else {
+		candidate = CleanPath(requestPath)
+	}
+
+	if candidate == requestPath {
+		return ErrNotFound
+	}
+
+	if _, matchErr := attempt(candidate); matchErr == nil {
+		result.RedirectPath = candidate
+		return ErrRedirect
+	}
+
+	return ErrNotFound
+}
+
+// match performs the actual method+path lookup, without the CleanPath/
+// RedirectTrailingSlash retry that Match layers on top of it. It reports whether
+// reaching the match required stripping a trailing slash from requestPath.
+func (m *RouteMux) match(method string, requestPath string, result *Result) (bool, error) {
 	result.Data = nil
 	result.Params = result.params[:0]
+	result.node = nil
 
 	// Get the root route from the methods map.
 	r, ok := m.methods[method]
 	if !ok {
-		return ErrNotFound
+		return false, ErrNotFound
 	}
 
+	node, trimmedSlash, err := matchPath(r, requestPath, result)
+	result.node = node
+	return trimmedSlash, err
+}
+
+// matchPath walks root's trie for requestPath, writing the match into result (which the
+// caller must have already reset) and returning the matched node, so that callers
+// layering additional constraints on top (such as MatchRequest's header check) can
+// inspect it. The second return value reports whether requestPath carried a trailing
+// slash that had to be stripped to reach the match, so callers implementing
+// RouteMux.RedirectTrailingSlash can tell that apart from an exact match.
+func matchPath(root *route, requestPath string, result *Result) (*route, bool, error) {
+	r := root
+
 	// Remove leading and trailing slashes from the request path.
 	l := len(requestPath)
 	for l > 0 && requestPath[0] == '/' {
 		requestPath = requestPath[1:]
 		l--
 	}
+	trimmedSlash := l > 0 && requestPath[l-1] == '/'
 	for l > 0 && requestPath[l-1] == '/' {
 		requestPath = requestPath[:l-1]
 		l--
@@ -300,12 +493,13 @@ func (m *RouteMux) Match(method string, requestPath string, result *Result) erro
 
 	if l == 0 {
 		result.Data = r.data
-		return nil
+		return r, trimmedSlash, nil
 	}
 
 	wildcard := false
 	var wildcardResult Result
 	var wildcardPath string
+	var wildcardNode *route
 
 pathloop:
 	for {
@@ -315,8 +509,14 @@ pathloop:
 			wildcardResult.Params = result.Params
 			wildcardResult.Data = r.data
 			wildcardPath = requestPath
+			wildcardNode = r
 		}
 
+		// Remember the path remaining at this node, in case nothing further down
+		// matches and a transcode route (see AddTranscodeRoute) registered here needs
+		// to be tried against it below.
+		remaining := requestPath
+
 		// Extract next path part.
 		part := requestPath
 		index := strings.IndexByte(requestPath, '/')
@@ -354,7 +554,9 @@ pathloop:
 			}
 		}
 
-		// Default to variable route but test regexp routes.
+		// Static routes above already take priority over variables. Among
+		// variables, a regex route (tried in registration order) takes priority over
+		// the plain variable route, which is the default if none match.
 		next := r.variable
 		for _, varRoute := range r.variables {
 			if varRoute.regex.MatchString(part) {
@@ -363,8 +565,16 @@ pathloop:
 			}
 		}
 
-		// Not found, break out.
+		// Not found. Fall back to any transcode routes (see AddTranscodeRoute)
+		// registered at this node before giving up, so a more specific static/variable
+		// route always takes priority over a transcode registered at the same point in
+		// the trie.
 		if next == nil {
+			if len(r.transcodes) > 0 {
+				if node := r.matchTranscodes(remaining, result); node != nil {
+					return node, trimmedSlash, nil
+				}
+			}
 			break pathloop
 		}
 
@@ -389,6 +599,7 @@ pathloop:
 			Name:  "*",
 			Value: wildcardPath,
 		})
+		r = wildcardNode
 	}
 
 	// If a match was found, set all the path parameter names and return successfully.
@@ -396,11 +607,11 @@ pathloop:
 		for i, name := range r.paramNames {
 			result.Params[i].Name = name
 		}
-		return nil
+		return r, trimmedSlash, nil
 	}
 
 	// No match was found.
-	return ErrNotFound
+	return nil, false, ErrNotFound
 }
 
 // Param returns the path parameter value for a given name.
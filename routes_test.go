@@ -149,6 +149,62 @@ func TestNotFound(t *testing.T) {
 	assert.Equal(t, ErrNotFound, err)
 }
 
+func TestRegexAnchored(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/person/:id([0-9]+)", "details")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/person/12a", &result)
+	assert.Equal(t, ErrNotFound, err)
+
+	err = handler.Match(GET, "/person/12", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "details", result.Data)
+}
+
+func TestStrictRegexPreservesSubstringMatching(t *testing.T) {
+	var handler RouteMux
+	handler.StrictRegex = true
+
+	err := handler.GET("/person/:id([0-9]+)", "details")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/person/12a", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "details", result.Data)
+	assert.Equal(t, "12a", result.Param("id"))
+}
+
+func TestSpecificityOrder(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/files/*", "wildcard")
+	require.Nil(t, err)
+	err = handler.GET("/files/:name", "variable")
+	require.Nil(t, err)
+	err = handler.GET("/files/:id([0-9]+)", "regex-variable")
+	require.Nil(t, err)
+	err = handler.GET("/files/report", "static")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/files/report", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "static", result.Data)
+
+	err = handler.Match(GET, "/files/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "regex-variable", result.Data)
+
+	err = handler.Match(GET, "/files/notes", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "variable", result.Data)
+
+	err = handler.Match(GET, "/files/a/b", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "wildcard", result.Data)
+}
+
 func Benchmark_Details_Collection(b *testing.B) {
 	handler := New()
 	registerResources(handler, resources)
@@ -0,0 +1,333 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrEmptyPattern denotes that CompilePattern was given an empty template.
+var ErrEmptyPattern = errors.New("Pattern must not be empty")
+
+// opCode identifies an instruction in a compiled Pattern's match program, following the
+// design of grpc-gateway's httprule compiler.
+type opCode int
+
+const (
+	opLiteral opCode = iota
+	opPush
+	opPushM
+	opPop
+	opEnd
+	opLiteralVerb
+)
+
+// op is one instruction of a compiled Pattern.
+type op struct {
+	code opCode
+
+	// value holds the literal text for opLiteral, the verb for opLiteralVerb, or the
+	// field name for opPush/opPushM/opPop (empty for the anonymous "*"/"**" forms,
+	// whether top-level or inside a variable's sub-template).
+	value string
+
+	// consume is true for opPush/opPushM instructions that themselves advance past a
+	// path segment, as opposed to ones that merely open a capture around following ops.
+	consume bool
+}
+
+// Pattern is a compiled google.api.http path template, e.g. "/v1/{name=shelves/*/books/*}".
+type Pattern struct {
+	raw  string
+	ops  []op
+	verb string
+}
+
+// CompilePattern compiles a google.api.http path template into a Pattern, for use with
+// RouteMux.AddTranscodeRoute. Supported syntax: literal segments, "*" (single-segment
+// wildcard), "**" (greedy, multi-segment wildcard), "{field}" and
+// "{field=sub/template}" variable captures (whose sub-template may itself contain "*"
+// and "**"), and a trailing ":verb" suffix.
+func CompilePattern(pattern string) (*Pattern, error) {
+	if pattern == "" {
+		return nil, ErrEmptyPattern
+	}
+
+	p := strings.TrimPrefix(pattern, "/")
+
+	verb := ""
+	if idx := strings.LastIndexByte(p, ':'); idx != -1 && !strings.Contains(p[idx:], "/") {
+		verb = p[idx+1:]
+		p = p[:idx]
+	}
+
+	if p == "" {
+		return nil, ErrBadSyntax
+	}
+
+	segments := strings.Split(p, "/")
+
+	var ops []op
+	for i := 0; i < len(segments); i++ {
+		seg := segments[i]
+		switch {
+		case seg == "":
+			return nil, ErrBadSyntax
+
+		case seg == "*":
+			ops = append(ops, op{code: opPush, consume: true})
+
+		case seg == "**":
+			ops = append(ops, op{code: opPushM, consume: true})
+
+		case seg[0] == '{':
+			field, sub, end, err := parseVariable(segments, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+			ops = append(ops, compileVariable(field, sub)...)
+
+		default:
+			ops = append(ops, op{code: opLiteral, value: seg})
+		}
+	}
+
+	if verb != "" {
+		ops = append(ops, op{code: opLiteralVerb, value: verb})
+	}
+	ops = append(ops, op{code: opEnd})
+
+	return &Pattern{raw: pattern, ops: ops, verb: verb}, nil
+}
+
+// compileVariable compiles a single "{field}" or "{field=sub}" capture into ops. A bare
+// field or a "*"/"**" sub-template consumes exactly one segment (or the greedy
+// remainder) and is bound directly; any other sub-template is compiled as its own
+// anonymous ops bracketed by a capture that spans however many segments it consumes.
+func compileVariable(field, sub string) []op {
+	switch sub {
+	case "", "*":
+		return []op{
+			{code: opPush, value: field, consume: true},
+			{code: opPop, value: field},
+		}
+	case "**":
+		return []op{
+			{code: opPushM, value: field, consume: true},
+			{code: opPop, value: field},
+		}
+	}
+
+	ops := []op{{code: opPush, value: field}}
+	for _, s := range strings.Split(sub, "/") {
+		switch s {
+		case "*":
+			ops = append(ops, op{code: opPush, consume: true})
+		case "**":
+			ops = append(ops, op{code: opPushM, consume: true})
+		default:
+			ops = append(ops, op{code: opLiteral, value: s})
+		}
+	}
+	ops = append(ops, op{code: opPop, value: field})
+
+	return ops
+}
+
+// parseVariable scans segments starting at index i for a "{field}" or "{field=sub}"
+// variable, which may span multiple slash-separated segments when sub contains "/". It
+// returns the field name, the raw sub-template (empty if none was given), and the index
+// of the segment that closes the variable.
+func parseVariable(segments []string, i int) (field, sub string, end int, err error) {
+	end = i
+	for !strings.HasSuffix(segments[end], "}") {
+		end++
+		if end >= len(segments) {
+			return "", "", i, ErrBadSyntax
+		}
+	}
+
+	body := strings.Join(segments[i:end+1], "/")
+	body = body[1 : len(body)-1] // strip "{" and "}"
+
+	if eq := strings.IndexByte(body, '='); eq != -1 {
+		field, sub = body[:eq], body[eq+1:]
+	} else {
+		field = body
+	}
+
+	if field == "" {
+		return "", "", i, ErrBadSyntax
+	}
+
+	return field, sub, end, nil
+}
+
+// match runs p's op program against path (which must already have any ":verb" suffix
+// removed), returning the bound field captures if the whole path is consumed.
+func (p *Pattern) match(path string) ([]Param, bool) {
+	segs := strings.Split(path, "/")
+	i := 0
+
+	var params []Param
+	var starts []int
+
+	for idx := 0; idx < len(p.ops); idx++ {
+		o := p.ops[idx]
+
+		switch o.code {
+		case opLiteralVerb:
+			// The verb was already matched by the caller against the request path.
+			continue
+
+		case opLiteral:
+			if i >= len(segs) || segs[i] != o.value {
+				return nil, false
+			}
+			i++
+
+		case opPush:
+			if o.value != "" {
+				starts = append(starts, i)
+			}
+			if o.consume {
+				if i >= len(segs) {
+					return nil, false
+				}
+				i++
+			}
+
+		case opPushM:
+			if o.value != "" {
+				starts = append(starts, i)
+			}
+			if o.consume {
+				trailing := segmentsConsumedAfter(p.ops[idx+1:])
+				take := len(segs) - i - trailing
+				if take < 0 {
+					return nil, false
+				}
+				i += take
+			}
+
+		case opPop:
+			start := starts[len(starts)-1]
+			starts = starts[:len(starts)-1]
+			params = append(params, Param{Name: o.value, Value: strings.Join(segs[start:i], "/")})
+
+		case opEnd:
+			return params, i == len(segs)
+		}
+	}
+
+	return params, i == len(segs)
+}
+
+// segmentsConsumedAfter returns the number of path segments that the remaining ops are
+// guaranteed to consume, so a greedy "**" knows how many trailing segments to leave for
+// the rest of the program.
+func segmentsConsumedAfter(ops []op) int {
+	count := 0
+	for _, o := range ops {
+		switch o.code {
+		case opLiteral:
+			count++
+		case opPush:
+			if o.consume {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// splitVerb splits a request path on its optional trailing ":verb" suffix, mirroring
+// the suffix CompilePattern strips from a pattern.
+func splitVerb(path string) (string, string) {
+	if idx := strings.LastIndexByte(path, ':'); idx != -1 && !strings.Contains(path[idx:], "/") {
+		return path[:idx], path[idx+1:]
+	}
+	return path, ""
+}
+
+// transcodeRoute pairs a compiled Pattern with the data registered for it.
+type transcodeRoute struct {
+	pattern *Pattern
+	route   *route
+}
+
+// AddTranscodeRoute registers a route using the google.api.http template dialect (see
+// CompilePattern) instead of the ":name"/"*" syntax AddRoute accepts. Transcode routes
+// coexist with ordinary routes for the same method, attached to the trie node reached by
+// walking pattern's leading literal segments (the same way AddRoute does), so a
+// transcode pattern can be nested under a prefix already built by AddRoute, Mount, or
+// another transcode route. At match time, the remaining (non-literal-prefix) part of
+// pattern is tried against the request path at that node only once nothing more specific
+// matches there; see matchPath.
+func (m *RouteMux) AddTranscodeRoute(method, pattern string, data interface{}) error {
+	root, remainder := literalPrefix(m.methodRoot(method), pattern)
+
+	compiled, err := CompilePattern(remainder)
+	if err != nil {
+		return err
+	}
+
+	root.transcodes = append(root.transcodes, &transcodeRoute{
+		pattern: compiled,
+		route:   &route{data: data},
+	})
+
+	return nil
+}
+
+// literalPrefix walks r down pattern's leading literal segments (those that are neither
+// a "{...}" variable, a "*"/"**" wildcard, nor carry a ":verb" suffix), the same way
+// resolveNodeFrom walks static segments, and returns the node reached along with
+// whatever of pattern was not consumed doing so.
+func literalPrefix(r *route, pattern string) (*route, string) {
+	p := strings.TrimPrefix(pattern, "/")
+
+	for p != "" {
+		seg, rest := p, ""
+		if idx := strings.IndexByte(p, '/'); idx != -1 {
+			seg, rest = p[:idx], p[idx+1:]
+		}
+
+		if seg == "" || seg[0] == '{' || seg == "*" || seg == "**" || strings.ContainsRune(seg, ':') {
+			return r, p
+		}
+
+		r = r.staticChild(seg)
+		p = rest
+	}
+
+	return r, p
+}
+
+// matchTranscodes tries r's transcode routes, in registration order, against
+// requestPath. On success it populates result and returns the matched node.
+func (r *route) matchTranscodes(requestPath string, result *Result) *route {
+	path, verb := splitVerb(requestPath)
+
+	for _, t := range r.transcodes {
+		if t.pattern.verb != verb {
+			continue
+		}
+
+		params, ok := t.pattern.match(path)
+		if !ok {
+			continue
+		}
+
+		result.Data = t.route.data
+		result.Params = append(result.Params[:0], params...)
+		return t.route
+	}
+
+	return nil
+}
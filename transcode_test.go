@@ -0,0 +1,87 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePatternLiteralAndVariable(t *testing.T) {
+	p, err := CompilePattern("/v1/shelves/{shelf}/books/{book}")
+	require.Nil(t, err)
+
+	params, ok := p.match("v1/shelves/5/books/2")
+	require.True(t, ok)
+	assert.Equal(t, []Param{{Name: "shelf", Value: "5"}, {Name: "book", Value: "2"}}, params)
+
+	_, ok = p.match("v1/shelves/5")
+	assert.False(t, ok)
+}
+
+func TestCompilePatternSubTemplate(t *testing.T) {
+	p, err := CompilePattern("/v1/{name=shelves/*}")
+	require.Nil(t, err)
+
+	params, ok := p.match("v1/shelves/5")
+	require.True(t, ok)
+	assert.Equal(t, []Param{{Name: "name", Value: "shelves/5"}}, params)
+}
+
+func TestCompilePatternVerb(t *testing.T) {
+	p, err := CompilePattern("/v1/{name}:cancel")
+	require.Nil(t, err)
+
+	path, verb := splitVerb("v1/widgets:cancel")
+	assert.Equal(t, "cancel", verb)
+
+	params, ok := p.match(path)
+	require.True(t, ok)
+	assert.Equal(t, []Param{{Name: "name", Value: "widgets"}}, params)
+}
+
+func TestCompilePatternEmpty(t *testing.T) {
+	_, err := CompilePattern("")
+	assert.Equal(t, ErrEmptyPattern, err)
+}
+
+func TestAddTranscodeRouteNestedUnderPrefix(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.GET("/v1/users/:id", "user-route")
+	require.Nil(t, err)
+
+	err = handler.AddTranscodeRoute(GET, "/v1/shelves/{id}", "shelf-route")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/v1/shelves/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "shelf-route", result.Data)
+	assert.Equal(t, "42", result.Param("id"))
+
+	err = handler.Match(GET, "/v1/users/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "user-route", result.Data)
+}
+
+func TestTranscodeRouteYieldsToMoreSpecificStaticRoute(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddTranscodeRoute(GET, "/v1/shelves/{id}", "shelf-route")
+	require.Nil(t, err)
+
+	err = handler.GET("/v1/shelves/static", "static-route")
+	require.Nil(t, err)
+
+	err = handler.Match(GET, "/v1/shelves/static", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "static-route", result.Data)
+
+	err = handler.Match(GET, "/v1/shelves/42", &result)
+	require.Nil(t, err)
+	assert.Equal(t, "shelf-route", result.Data)
+}
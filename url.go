@@ -0,0 +1,98 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrMissingParam denotes that URL was not given a value for one of the route's
+	// path parameters.
+	ErrMissingParam = errors.New("Missing value for path parameter")
+	// ErrParamMismatch denotes that a value passed to URL does not satisfy the
+	// corresponding path parameter's regular expression.
+	ErrParamMismatch = errors.New("Path parameter value does not match route pattern")
+)
+
+// urlSegment describes one path part of a registered pattern, enough to reconstruct a
+// concrete path from parameter values.
+type urlSegment struct {
+	literal  string         // set for static segments
+	param    string         // set for variable and wildcard segments
+	regex    *regexp.Regexp // non-nil if the variable had a custom expression
+	wildcard bool
+}
+
+// AddNamedRoute adds a new route, as AddRoute does, and additionally registers it under
+// name so it can later be reconstructed with URL or URLPath.
+func (m *RouteMux) AddNamedRoute(method, pattern, name string, data interface{}) error {
+	r, paramNames, err := m.resolveNode(method, pattern)
+	if err != nil {
+		return err
+	}
+
+	r.data = data
+	r.paramNames = paramNames
+
+	if m.names == nil {
+		m.names = make(map[string]*route, 10)
+	}
+	m.names[name] = r
+
+	return nil
+}
+
+// URL reconstructs a concrete path for the route registered under name, substituting
+// params, which must alternate parameter name and value (as in gorilla/mux's Route.URL),
+// for the corresponding path parameters. It returns ErrNotFound if no route is
+// registered under name, ErrMissingParam if a path parameter has no corresponding value,
+// and ErrParamMismatch if a value does not satisfy the parameter's regular expression.
+func (m *RouteMux) URL(name string, params ...string) (string, error) {
+	r, ok := m.names[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	if len(params)%2 != 0 {
+		return "", ErrBadSyntax
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var b strings.Builder
+	for _, seg := range r.segments {
+		b.WriteByte('/')
+
+		if seg.param == "" {
+			b.WriteString(seg.literal)
+			continue
+		}
+
+		value, ok := values[seg.param]
+		if !ok {
+			return "", ErrMissingParam
+		}
+		if seg.regex != nil && !seg.regex.MatchString(value) {
+			return "", ErrParamMismatch
+		}
+
+		b.WriteString(value)
+	}
+
+	return b.String(), nil
+}
+
+// URLPath is equivalent to URL; it is provided for symmetry with APIs (such as
+// gorilla/mux) that distinguish a path-only reconstruction from one that also includes
+// a matched host.
+func (m *RouteMux) URLPath(name string, params ...string) (string, error) {
+	return m.URL(name, params...)
+}
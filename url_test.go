@@ -0,0 +1,71 @@
+// Copyright 2017 The Prizem Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routerstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddNamedRoute(GET, "/person/:last/:first", "person", "details")
+	require.Nil(t, err)
+
+	path, err := handler.URL("person", "last", "anderson", "first", "thomas")
+	require.Nil(t, err)
+	assert.Equal(t, "/person/anderson/thomas", path)
+}
+
+func TestURLPath(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddNamedRoute(GET, "/person/:id([0-9]+)", "person", "details")
+	require.Nil(t, err)
+
+	path, err := handler.URLPath("person", "id", "42")
+	require.Nil(t, err)
+	assert.Equal(t, "/person/42", path)
+}
+
+func TestURLNotFound(t *testing.T) {
+	var handler RouteMux
+
+	_, err := handler.URL("missing")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestURLMissingParam(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddNamedRoute(GET, "/person/:last/:first", "person", "details")
+	require.Nil(t, err)
+
+	_, err = handler.URL("person", "last", "anderson")
+	assert.Equal(t, ErrMissingParam, err)
+}
+
+func TestURLParamMismatch(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddNamedRoute(GET, "/person/:id([0-9]+)", "person", "details")
+	require.Nil(t, err)
+
+	_, err = handler.URL("person", "id", "abcd")
+	assert.Equal(t, ErrParamMismatch, err)
+}
+
+func TestURLBadSyntax(t *testing.T) {
+	var handler RouteMux
+
+	err := handler.AddNamedRoute(GET, "/person/:id", "person", "details")
+	require.Nil(t, err)
+
+	_, err = handler.URL("person", "id")
+	assert.Equal(t, ErrBadSyntax, err)
+}